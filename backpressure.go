@@ -0,0 +1,255 @@
+package gwr
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"text/template"
+)
+
+// OverflowPolicy controls what a watch stream writer's bounded per-writer
+// buffer does when an incoming frame would exceed its capacity.
+type OverflowPolicy int
+
+const (
+	// OverflowDropOldest discards the oldest buffered frame to make room for
+	// the incoming one. It is the default.
+	OverflowDropOldest OverflowPolicy = iota
+
+	// OverflowDropNewest discards the incoming frame, keeping what's already
+	// buffered.
+	OverflowDropNewest
+
+	// OverflowDisconnect drops the writer entirely, same as a write error.
+	OverflowDisconnect
+)
+
+// DefaultWatchBufferSize is the per-writer buffer depth used for any format
+// without its own BufferedDataFormat.WatchBufferConfig.
+const DefaultWatchBufferSize = 64
+
+// WatchBufferConfig configures the bounded per-writer buffer that every
+// watch stream writer of a format gets.
+type WatchBufferConfig struct {
+	// Size is the number of frames buffered per writer before Overflow
+	// applies. Zero means DefaultWatchBufferSize.
+	Size int
+
+	// Overflow is the policy applied when a writer's buffer is full.
+	Overflow OverflowPolicy
+}
+
+// BufferedDataFormat is implemented by a GenericDataFormat that wants to
+// override the default watch stream buffering (DefaultWatchBufferSize,
+// OverflowDropOldest) for its own format.
+type BufferedDataFormat interface {
+	GenericDataFormat
+	WatchBufferConfig() WatchBufferConfig
+}
+
+func bufferConfigFor(format GenericDataFormat) WatchBufferConfig {
+	if bf, ok := format.(BufferedDataFormat); ok {
+		cfg := bf.WatchBufferConfig()
+		if cfg.Size <= 0 {
+			cfg.Size = DefaultWatchBufferSize
+		}
+		return cfg
+	}
+	return WatchBufferConfig{Size: DefaultWatchBufferSize, Overflow: OverflowDropOldest}
+}
+
+// WatcherStats tracks backpressure metrics for a single watch stream writer:
+// how many frames it has dropped, and its current and historical peak queue
+// depth. The built-in "gwr/watchers" data source reports a snapshot of every
+// live WatcherStats so operators can observe backpressure.
+type WatcherStats struct {
+	Source string
+	Format string
+
+	drops    int64
+	depth    int64
+	maxDepth int64
+}
+
+func (s *WatcherStats) addDrop() { atomic.AddInt64(&s.drops, 1) }
+
+func (s *WatcherStats) setDepth(n int) {
+	d := int64(n)
+	atomic.StoreInt64(&s.depth, d)
+	for {
+		max := atomic.LoadInt64(&s.maxDepth)
+		if d <= max || atomic.CompareAndSwapInt64(&s.maxDepth, max, d) {
+			return
+		}
+	}
+}
+
+// WatcherStatsSnapshot is a point-in-time copy of a WatcherStats, safe to
+// marshal.
+type WatcherStatsSnapshot struct {
+	Source   string `json:"source"`
+	Format   string `json:"format"`
+	Drops    int64  `json:"drops"`
+	Depth    int64  `json:"depth"`
+	MaxDepth int64  `json:"maxDepth"`
+}
+
+// Snapshot copies s's current counters.
+func (s *WatcherStats) Snapshot() WatcherStatsSnapshot {
+	return WatcherStatsSnapshot{
+		Source:   s.Source,
+		Format:   s.Format,
+		Drops:    atomic.LoadInt64(&s.drops),
+		Depth:    atomic.LoadInt64(&s.depth),
+		MaxDepth: atomic.LoadInt64(&s.maxDepth),
+	}
+}
+
+var (
+	watcherStatsMu  sync.Mutex
+	watcherStatsReg = make(map[*WatcherStats]struct{})
+)
+
+func registerWatcherStats(s *WatcherStats) {
+	watcherStatsMu.Lock()
+	watcherStatsReg[s] = struct{}{}
+	watcherStatsMu.Unlock()
+}
+
+func unregisterWatcherStats(s *WatcherStats) {
+	watcherStatsMu.Lock()
+	delete(watcherStatsReg, s)
+	watcherStatsMu.Unlock()
+}
+
+func watcherStatsSnapshots() []WatcherStatsSnapshot {
+	watcherStatsMu.Lock()
+	defer watcherStatsMu.Unlock()
+	snaps := make([]WatcherStatsSnapshot, 0, len(watcherStatsReg))
+	for s := range watcherStatsReg {
+		snaps = append(snaps, s.Snapshot())
+	}
+	return snaps
+}
+
+// watcherSlot binds one watch stream writer to its own bounded frame buffer
+// and dedicated drain goroutine, so a slow or stuck consumer can never block
+// emission to any other watcher of the same defaultFrameWatcher, nor the
+// upstream GenericDataSource.Watch callback that feeds it.
+type watcherSlot struct {
+	mu       sync.Mutex
+	w        io.Writer
+	frames   chan []byte
+	overflow OverflowPolicy
+	closed   bool
+	stats    *WatcherStats
+}
+
+func newWatcherSlot(w io.Writer, cfg WatchBufferConfig, stats *WatcherStats) *watcherSlot {
+	ws := &watcherSlot{
+		w:        w,
+		frames:   make(chan []byte, cfg.Size),
+		overflow: cfg.Overflow,
+		stats:    stats,
+	}
+	registerWatcherStats(stats)
+	go ws.drain()
+	return ws
+}
+
+// push enqueues buf for this watcher's drain goroutine without ever
+// blocking the caller. On overflow it applies the slot's OverflowPolicy; it
+// reports false once the slot is (or just became) closed, so the caller can
+// drop this watcher from its fan-out set.
+func (ws *watcherSlot) push(buf []byte) bool {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.closed {
+		return false
+	}
+
+	select {
+	case ws.frames <- buf:
+		ws.stats.setDepth(len(ws.frames))
+		return true
+	default:
+	}
+
+	switch ws.overflow {
+	case OverflowDropNewest:
+		ws.stats.addDrop()
+		return true
+	case OverflowDisconnect:
+		ws.stats.addDrop()
+		ws.closeLocked()
+		return false
+	default: // OverflowDropOldest
+		select {
+		case <-ws.frames:
+			ws.stats.addDrop()
+		default:
+		}
+		ws.frames <- buf // can't block: push is the only sender, and we hold mu
+		ws.stats.setDepth(len(ws.frames))
+		return true
+	}
+}
+
+func (ws *watcherSlot) drain() {
+	for buf := range ws.frames {
+		if _, err := ws.w.Write(buf); err != nil {
+			ws.close()
+			return
+		}
+		ws.stats.setDepth(len(ws.frames))
+	}
+}
+
+func (ws *watcherSlot) close() {
+	ws.mu.Lock()
+	ws.closeLocked()
+	ws.mu.Unlock()
+}
+
+// closeWriter disconnects the slot and, if its writer implements io.Closer,
+// closes it too. A writer that is not an io.Closer (protocol's chanBuf is
+// not one) is only disconnected: it stops receiving frames and is not
+// otherwise told so.
+func (ws *watcherSlot) closeWriter() {
+	ws.close()
+	if c, ok := ws.w.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+func (ws *watcherSlot) closeLocked() {
+	if ws.closed {
+		return
+	}
+	ws.closed = true
+	close(ws.frames)
+	unregisterWatcherStats(ws.stats)
+}
+
+// watchersDataSource implements GenericDataSource over the live watch stream
+// backpressure stats registered by every defaultFrameWatcher slot.
+type watchersDataSource struct{}
+
+func (watchersDataSource) Name() string { return "gwr/watchers" }
+
+func (watchersDataSource) Attrs() map[string]interface{} {
+	return map[string]interface{}{"desc": "watch stream backpressure stats"}
+}
+
+func (watchersDataSource) TextTemplate() *template.Template { return nil }
+
+func (watchersDataSource) Get() interface{} { return watcherStatsSnapshots() }
+
+func (watchersDataSource) GetInit() interface{} { return watcherStatsSnapshots() }
+
+func (watchersDataSource) Watch(GenericDataWatcher) {}
+
+func init() {
+	DefaultDataSources.Add(NewMarshaledDataSource(watchersDataSource{}, nil))
+}
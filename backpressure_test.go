@@ -0,0 +1,106 @@
+package gwr
+
+import (
+	"sync"
+	"testing"
+)
+
+// blockingWriter's Write closes started on its first call, then blocks until
+// release is closed, so a test can deterministically pin a watcherSlot's
+// drain goroutine mid-write and reason about exactly what's left buffered.
+type blockingWriter struct {
+	started chan struct{}
+	once    sync.Once
+	release chan struct{}
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.release
+	return len(p), nil
+}
+
+func newTestSlot(overflow OverflowPolicy, size int) (*watcherSlot, *blockingWriter, *WatcherStats) {
+	w := newBlockingWriter()
+	stats := &WatcherStats{Source: "test", Format: "test"}
+	ws := newWatcherSlot(w, WatchBufferConfig{Size: size, Overflow: overflow}, stats)
+	return ws, w, stats
+}
+
+// warmUp pushes one frame and waits for the drain goroutine to pick it up
+// and block on it, so the slot's buffer (capacity size) starts out empty
+// and every subsequent push's effect on it is deterministic.
+func warmUp(t *testing.T, ws *watcherSlot, w *blockingWriter) {
+	t.Helper()
+	if !ws.push([]byte("warmup")) {
+		t.Fatal("warmup push reported closed")
+	}
+	<-w.started
+}
+
+func TestWatcherSlotOverflowDropOldest(t *testing.T) {
+	ws, w, stats := newTestSlot(OverflowDropOldest, 2)
+	defer close(w.release)
+	warmUp(t, ws, w)
+
+	for i := 0; i < 4; i++ {
+		if !ws.push([]byte("x")) {
+			t.Fatalf("push %d reported closed under OverflowDropOldest", i)
+		}
+	}
+
+	snap := stats.Snapshot()
+	if snap.Drops != 2 {
+		t.Fatalf("drops = %d, want 2 (2 frames evicted to keep the buffer at size 2)", snap.Drops)
+	}
+	if snap.Depth != 2 {
+		t.Fatalf("depth = %d, want 2 (buffer stays full once it's overflowed)", snap.Depth)
+	}
+}
+
+func TestWatcherSlotOverflowDropNewest(t *testing.T) {
+	ws, w, stats := newTestSlot(OverflowDropNewest, 2)
+	defer close(w.release)
+	warmUp(t, ws, w)
+
+	for i := 0; i < 4; i++ {
+		if !ws.push([]byte("x")) {
+			t.Fatalf("push %d reported closed under OverflowDropNewest", i)
+		}
+	}
+
+	snap := stats.Snapshot()
+	if snap.Drops != 2 {
+		t.Fatalf("drops = %d, want 2 (the 2 frames that arrived once the buffer was full)", snap.Drops)
+	}
+	if snap.Depth != 2 {
+		t.Fatalf("depth = %d, want 2 (buffer fills then stays put)", snap.Depth)
+	}
+}
+
+func TestWatcherSlotOverflowDisconnect(t *testing.T) {
+	ws, w, stats := newTestSlot(OverflowDisconnect, 2)
+	defer close(w.release)
+	warmUp(t, ws, w)
+
+	for i := 0; i < 2; i++ {
+		if !ws.push([]byte("x")) {
+			t.Fatalf("push %d reported closed before the buffer filled", i)
+		}
+	}
+
+	if ws.push([]byte("x")) {
+		t.Fatal("push past capacity did not disconnect under OverflowDisconnect")
+	}
+	if ws.push([]byte("x")) {
+		t.Fatal("push on an already-closed slot should keep reporting closed")
+	}
+
+	if snap := stats.Snapshot(); snap.Drops != 1 {
+		t.Fatalf("drops = %d, want 1 (one drop recorded on disconnect)", snap.Drops)
+	}
+}
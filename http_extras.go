@@ -0,0 +1,80 @@
+package gwr
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/uber-go/gwr/protocol"
+	"github.com/uber-go/gwr/source"
+)
+
+// withExtras wraps the HTTP handler protocol.NewHTTPRest returns, mounting
+// additional sub-resources neither it nor the RESP/gRPC protocols know
+// about: a data source's "<name>.proto" descriptor (see
+// protocol.WriteProtoDescriptor), and any extra Route a RoutedDataSource
+// declares via MarshaledDataSource.Routes(). Anything else falls through to
+// next unchanged.
+func withExtras(dss *source.DataSources, prefix string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, rest, ok := splitDataSourcePath(prefix, r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if rest == "" && strings.HasSuffix(name, ".proto") {
+			if ds, ok := dss.Get(strings.TrimSuffix(name, ".proto")); ok {
+				if mds, ok := ds.(*MarshaledDataSource); ok {
+					protocol.WriteProtoDescriptor(w, mds)
+					return
+				}
+			}
+		}
+
+		if rest != "" {
+			if ds, ok := dss.Get(name); ok {
+				if mds, ok := ds.(*MarshaledDataSource); ok {
+					for _, route := range mds.Routes() {
+						if route.Path == rest && route.Method == r.Method {
+							formatName := formatFromAccept(r.Header.Get("Accept"))
+							if err := route.Call(formatName, r.URL.Query(), w); err != nil {
+								http.Error(w, err.Error(), http.StatusInternalServerError)
+							}
+							return
+						}
+					}
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// formatFromAccept picks the format name a request's Accept header asks for,
+// defaulting to "json" same as the rest of the HTTP protocol does.
+func formatFromAccept(accept string) string {
+	switch {
+	case strings.Contains(accept, "application/x-protobuf"):
+		return "proto"
+	case strings.Contains(accept, "text/plain"):
+		return "text"
+	default:
+		return "json"
+	}
+}
+
+// splitDataSourcePath strips prefix from path and splits what's left into a
+// data source name and any remaining sub-path, e.g. "tail" out of
+// "/gwr/log/tail" for prefix "/gwr". ok is false if path doesn't name a data
+// source at all (empty after stripping prefix).
+func splitDataSourcePath(prefix, path string) (name, rest string, ok bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, prefix), "/")
+	if path == "" {
+		return "", "", false
+	}
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		return path[:i], path[i+1:], true
+	}
+	return path, "", true
+}
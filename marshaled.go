@@ -6,6 +6,8 @@ import (
 	"log"
 	"strings"
 	"text/template"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 // TODO: punts on any locking concerns
@@ -91,9 +93,11 @@ type marshaledWatcher struct {
 	watchers []ItemWatcher
 }
 
-func newMarshaledWatcher(source GenericDataSource, format GenericDataFormat) *marshaledWatcher {
+func newMarshaledWatcher(source GenericDataSource, formatName string, format GenericDataFormat) *marshaledWatcher {
 	gw := &marshaledWatcher{source: source, format: format}
 	gw.dfw.format = format
+	gw.dfw.sourceName = source.Name()
+	gw.dfw.formatNameStr = formatName
 	return gw
 }
 
@@ -101,7 +105,7 @@ func (gw *marshaledWatcher) init(w io.Writer) error {
 	if err := gw.dfw.init(gw.source.GetInit(), w); err != nil {
 		return err
 	}
-	if len(gw.dfw.writers) == 1 {
+	if len(gw.dfw.slots) == 1 {
 		gw.watchers = append(gw.watchers, &gw.dfw)
 	}
 	return nil
@@ -174,18 +178,32 @@ func NewMarshaledDataSource(
 		// a big deal
 		n++
 	}
+	if formats["proto"] == nil {
+		// may over estimate by one if source doesn't implement
+		// ProtoDescriptor; probably not a big deal
+		n++
+	}
 	watchers := make(map[string]*marshaledWatcher, n)
 
 	// standard json protocol
 	if formats["json"] == nil {
 		formatNames = append(formatNames, "json")
-		watchers["json"] = newMarshaledWatcher(source, LDJSONMarshal)
+		watchers["json"] = newMarshaledWatcher(source, "json", LDJSONMarshal)
 	}
 
 	// convenience templated text protocol
 	if tt := source.TextTemplate(); tt != nil && formats["text"] == nil {
 		formatNames = append(formatNames, "text")
-		watchers["text"] = newMarshaledWatcher(source, NewTemplatedMarshal(tt))
+		watchers["text"] = newMarshaledWatcher(source, "text", NewTemplatedMarshal(tt))
+	}
+
+	// first-class proto protocol, opt in by implementing ProtoDescriptor
+	// rather than speculatively calling Get() to sniff the type: Get may be a
+	// live round-trip (e.g. a plugin-hosted source), and nothing should pay
+	// for one before a client actually asks for data.
+	if _, ok := source.(ProtoDescriptor); ok && formats["proto"] == nil {
+		formatNames = append(formatNames, "proto")
+		watchers["proto"] = newMarshaledWatcher(source, "proto", ProtobufMarshal)
 	}
 
 	// TODO: source should be able to declare some formats in addition to any
@@ -193,7 +211,7 @@ func NewMarshaledDataSource(
 
 	for name, format := range formats {
 		formatNames = append(formatNames, name)
-		watchers[name] = newMarshaledWatcher(source, format)
+		watchers[name] = newMarshaledWatcher(source, name, format)
 	}
 
 	return &MarshaledDataSource{
@@ -220,6 +238,25 @@ func (mds *MarshaledDataSource) Attrs() map[string]interface{} {
 	return mds.source.Attrs()
 }
 
+// Close disconnects every live watcher of mds, across all formats, closing
+// each watcher's underlying io.Writer if it implements io.Closer. It's used
+// to tear down a data source whose backing implementation has gone away
+// (e.g. a plugin process that exited). A watcher whose writer is not an
+// io.Closer stops receiving items but is not otherwise told the source is
+// gone.
+func (mds *MarshaledDataSource) Close() {
+	for _, watcher := range mds.watchers {
+		watcher.dfw.closeAll()
+	}
+}
+
+// ProtoDescriptor returns the wrapped data source's protoreflect.MessageDescriptor
+// and true, if it declares one by implementing ProtoDescriptor; this backs
+// the "proto" format's ".proto" sub-resource.
+func (mds *MarshaledDataSource) ProtoDescriptor() (protoreflect.MessageDescriptor, bool) {
+	return protoDescriptorOf(mds.source)
+}
+
 // Get marshals data source's Get data to the writer
 func (mds *MarshaledDataSource) Get(formatName string, w io.Writer) error {
 	format, ok := mds.formats[strings.ToLower(formatName)]
@@ -279,9 +316,17 @@ func (mds *MarshaledDataSource) emit(data interface{}) bool {
 
 var errDefaultFrameWatcherDone = errors.New("all defaultFrameWatcher writers done")
 
+// defaultFrameWatcher fans marshaled, framed items out to every writer
+// watching a MarshaledDataSource in one format. Each writer gets its own
+// watcherSlot: a bounded buffer served by a dedicated goroutine, so a slow
+// or stuck writer only loses frames, or is dropped, on its own (per its
+// format's WatchBufferConfig) rather than blocking emission to every other
+// writer or the upstream GenericDataSource.Watch callback.
 type defaultFrameWatcher struct {
-	format  GenericDataFormat
-	writers []io.Writer
+	format        GenericDataFormat
+	sourceName    string
+	formatNameStr string
+	slots         []*watcherSlot
 }
 
 func (dfw *defaultFrameWatcher) init(data interface{}, w io.Writer) error {
@@ -300,12 +345,13 @@ func (dfw *defaultFrameWatcher) init(data interface{}, w io.Writer) error {
 			return err
 		}
 	}
-	dfw.writers = append(dfw.writers, w)
+	stats := &WatcherStats{Source: dfw.sourceName, Format: dfw.formatNameStr}
+	dfw.slots = append(dfw.slots, newWatcherSlot(w, bufferConfigFor(dfw.format), stats))
 	return nil
 }
 
 func (dfw *defaultFrameWatcher) HandleItem(item []byte) error {
-	if len(dfw.writers) == 0 {
+	if len(dfw.slots) == 0 {
 		return errDefaultFrameWatcherDone
 	}
 	if buf, err := dfw.format.FrameItem(item); err != nil {
@@ -318,7 +364,7 @@ func (dfw *defaultFrameWatcher) HandleItem(item []byte) error {
 }
 
 func (dfw *defaultFrameWatcher) HandleItems(items [][]byte) error {
-	if len(dfw.writers) == 0 {
+	if len(dfw.slots) == 0 {
 		return errDefaultFrameWatcherDone
 	}
 	for _, item := range items {
@@ -332,15 +378,26 @@ func (dfw *defaultFrameWatcher) HandleItems(items [][]byte) error {
 	return nil
 }
 
-func (dfw *defaultFrameWatcher) writeToAll(buf []byte) error {
-	// TODO: avoid blocking fan out, parallelize; error back-propagation then
-	// needs to happen over another channel
+// closeAll disconnects every writer currently attached to dfw, closing each
+// one's underlying io.Writer if it implements io.Closer.
+func (dfw *defaultFrameWatcher) closeAll() {
+	slots := dfw.slots
+	dfw.slots = nil
+	for _, slot := range slots {
+		slot.closeWriter()
+	}
+}
 
+// writeToAll hands buf to every watcher's slot. Each slot's push is
+// non-blocking: a slow writer either absorbs the frame into its own bounded
+// buffer or, per its overflow policy, drops a frame rather than stall this
+// call or the upstream emitter.
+func (dfw *defaultFrameWatcher) writeToAll(buf []byte) error {
 	var failed []int // TODO: could carry this rather than allocate on failure
-	for i, w := range dfw.writers {
-		if _, err := w.Write(buf); err != nil {
+	for i, slot := range dfw.slots {
+		if !slot.push(buf) {
 			if failed == nil {
-				failed = make([]int, 0, len(dfw.writers))
+				failed = make([]int, 0, len(dfw.slots))
 			}
 			failed = append(failed, i)
 		}
@@ -350,29 +407,29 @@ func (dfw *defaultFrameWatcher) writeToAll(buf []byte) error {
 	}
 
 	var (
-		okay   []io.Writer
-		remain = len(dfw.writers) - len(failed)
+		okay   []*watcherSlot
+		remain = len(dfw.slots) - len(failed)
 	)
 	if remain > 0 {
-		okay = make([]io.Writer, 0, remain)
+		okay = make([]*watcherSlot, 0, remain)
 	}
-	for i, w := range dfw.writers {
+	for i, slot := range dfw.slots {
 		if i != failed[0] {
-			okay = append(okay, w)
+			okay = append(okay, slot)
 		}
 		if i >= failed[0] {
 			failed = failed[1:]
 			if len(failed) == 0 {
-				if j := i + 1; j < len(dfw.writers) {
-					okay = append(okay, dfw.writers[j:]...)
+				if j := i + 1; j < len(dfw.slots) {
+					okay = append(okay, dfw.slots[j:]...)
 				}
 				break
 			}
 		}
 	}
-	dfw.writers = okay
+	dfw.slots = okay
 
-	if len(dfw.writers) == 0 {
+	if len(dfw.slots) == 0 {
 		return errDefaultFrameWatcherDone
 	}
 	return nil
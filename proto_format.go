@@ -0,0 +1,79 @@
+package gwr
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrNotProtoMessage is returned when ProtobufMarshal is asked to marshal a
+// value that is not a proto.Message.
+var ErrNotProtoMessage = errors.New("value is not a proto.Message")
+
+// ProtoDescriptor is implemented by a GenericDataSource whose Get/GetInit/
+// watch items are themselves proto.Message values, to advertise the
+// protoreflect.MessageDescriptor they share. This both lets clients discover
+// the wire schema through the "proto" format's ".proto" sub-resource, and is
+// NewMarshaledDataSource's opt-in signal to auto-register the "proto"
+// format: it's deliberately not inferred by calling Get() and type-asserting
+// the result, since Get() isn't guaranteed cheap or side-effect-free (a
+// plugin-hosted source's Get is a live RPC).
+type ProtoDescriptor interface {
+	ProtoDescriptor() protoreflect.MessageDescriptor
+}
+
+// protobufMarshal implements GenericDataFormat over proto.Message values.
+type protobufMarshal struct{}
+
+// ProtobufMarshal is the "proto" GenericDataFormat: it serializes
+// proto.Message values with the standard protobuf wire format, and frames
+// watch stream items as length-prefixed messages (a varint length followed
+// by the serialized message), the same delimited encoding a gRPC-style
+// stream reader expects.
+var ProtobufMarshal GenericDataFormat = protobufMarshal{}
+
+func (protobufMarshal) marshal(data interface{}) ([]byte, error) {
+	msg, ok := data.(proto.Message)
+	if !ok {
+		return nil, ErrNotProtoMessage
+	}
+	return proto.Marshal(msg)
+}
+
+// MarshalGet serializes data, which must be a proto.Message.
+func (pm protobufMarshal) MarshalGet(data interface{}) ([]byte, error) {
+	return pm.marshal(data)
+}
+
+// MarshalInit serializes data, which must be a proto.Message.
+func (pm protobufMarshal) MarshalInit(data interface{}) ([]byte, error) {
+	return pm.marshal(data)
+}
+
+// MarshalItem serializes data, which must be a proto.Message.
+func (pm protobufMarshal) MarshalItem(data interface{}) ([]byte, error) {
+	return pm.marshal(data)
+}
+
+// FrameItem length-prefixes buf with a varint, so a watch stream can be read
+// back as a sequence of self-delimited messages without any other framing.
+func (protobufMarshal) FrameItem(buf []byte) ([]byte, error) {
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(lenBuf, uint64(len(buf)))
+	framed := make([]byte, 0, n+len(buf))
+	framed = append(framed, lenBuf[:n]...)
+	framed = append(framed, buf...)
+	return framed, nil
+}
+
+// protoDescriptorOf returns the ProtoDescriptor of source's message type, if
+// source declares one.
+func protoDescriptorOf(source GenericDataSource) (protoreflect.MessageDescriptor, bool) {
+	pd, ok := source.(ProtoDescriptor)
+	if !ok {
+		return nil, false
+	}
+	return pd.ProtoDescriptor(), true
+}
@@ -0,0 +1,178 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/uber-go/gwr/source"
+)
+
+// GRPCServer implements GWRServer on top of a source.DataSources registry,
+// mapping each rpc onto the same Get/Watch(format, io.Writer) contract that
+// the HTTP and RESP protocols already use.
+type GRPCServer struct {
+	dss *source.DataSources
+}
+
+// NewGRPCServer creates a GRPCServer serving the given data source registry.
+func NewGRPCServer(dss *source.DataSources) *GRPCServer {
+	return &GRPCServer{dss: dss}
+}
+
+var errNoSuchDataSource = errors.New("no such data source")
+
+func (gs *GRPCServer) dataSource(name string) (source.DataSource, error) {
+	ds, ok := gs.dss.Get(name)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%v: %q", errNoSuchDataSource, name)
+	}
+	return ds, nil
+}
+
+// List implements the List rpc, returning the names of all registered data
+// sources.
+func (gs *GRPCServer) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	return newListResponse(gs.dss.Names()), nil
+}
+
+// Info implements the Info rpc, returning a data source's supported formats
+// and its Attrs(), json-marshaled for transport.
+func (gs *GRPCServer) Info(ctx context.Context, req *InfoRequest) (*InfoResponse, error) {
+	ds, err := gs.dataSource(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := json.Marshal(ds.Attrs())
+	if err != nil {
+		return nil, err
+	}
+	return newInfoResponse(ds.Formats(), attrs), nil
+}
+
+// Get implements the Get rpc, marshaling a single snapshot of a data source
+// in the requested format.
+func (gs *GRPCServer) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	ds, err := gs.dataSource(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := ds.Get(req.GetFormat(), &buf); err != nil {
+		return nil, err
+	}
+	return newGetResponse(buf.Bytes()), nil
+}
+
+// Watch implements the Watch rpc, streaming framed, marshaled items from a
+// data source's watch stream until the client cancels. Watch registers a
+// grpcStreamWriter with the data source exactly like an HTTP or RESP watch
+// connection registers its own io.Writer; once the client goes away, the
+// stream's Send call starts failing and the data source's
+// defaultFrameWatcher drops the writer the same way it drops any other.
+func (gs *GRPCServer) Watch(req *WatchRequest, stream GWR_WatchServer) error {
+	ds, err := gs.dataSource(req.GetName())
+	if err != nil {
+		return err
+	}
+	if err := ds.Watch(req.GetFormat(), grpcStreamWriter{stream}); err != nil {
+		return err
+	}
+	<-stream.Context().Done()
+	return stream.Context().Err()
+}
+
+// grpcStreamWriter adapts a GWR_WatchServer into the io.Writer that
+// MarshaledDataSource.Watch expects, sending each already-framed write as one
+// WatchResponse message.
+type grpcStreamWriter struct {
+	stream GWR_WatchServer
+}
+
+func (w grpcStreamWriter) Write(p []byte) (int, error) {
+	buf := make([]byte, len(p))
+	copy(buf, p)
+	if err := w.stream.Send(newWatchResponse(buf)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// HTTP2Preface is the fixed preface (RFC 7540 §3.5) sent by any HTTP/2
+// client before its first frame; stacked.Server uses it to tell gRPC traffic
+// (always HTTP/2 over cleartext here) apart from HTTP/1.1 and RESP.
+var HTTP2Preface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+// IsHTTP2Preface reports whether buf is the full HTTP/2 connection preface.
+func IsHTTP2Preface(buf []byte) bool {
+	return bytes.Equal(buf, HTTP2Preface)
+}
+
+// ServeGRPCConn serves a single already-accepted connection with srv, for use
+// as a stacked.Detector handler once the HTTP/2 preface has been observed.
+// It blocks until srv is done with conn; the conn is wrapped so that when
+// grpc's transport closes it at the end of the connection, the listener
+// backing this one-shot Serve call closes too, instead of leaking the Serve
+// goroutine forever waiting on a second Accept that will never come.
+func ServeGRPCConn(srv *grpc.Server, conn net.Conn) {
+	lis := newSingleConnListener(nil)
+	lis.conn = &closeNotifyConn{Conn: conn, notify: lis.Close}
+	srv.Serve(lis)
+}
+
+// closeNotifyConn calls notify whenever Close is called; notify must be
+// idempotent, since the underlying transport may close the conn more than
+// once.
+type closeNotifyConn struct {
+	net.Conn
+	notify func() error
+}
+
+func (c *closeNotifyConn) Close() error {
+	err := c.Conn.Close()
+	c.notify()
+	return err
+}
+
+// singleConnListener adapts a single net.Conn, already claimed by a
+// stacked.Detector, into the net.Listener that grpc.Server.Serve expects.
+// Its first Accept returns the conn; every call after that blocks until
+// Close, at which point grpc.Server's Serve loop exits.
+type singleConnListener struct {
+	conn   net.Conn
+	taken  bool
+	closed chan struct{}
+}
+
+func newSingleConnListener(conn net.Conn) *singleConnListener {
+	return &singleConnListener{conn: conn, closed: make(chan struct{})}
+}
+
+func (l *singleConnListener) Accept() (net.Conn, error) {
+	if !l.taken {
+		l.taken = true
+		return l.conn, nil
+	}
+	<-l.closed
+	return nil, io.EOF
+}
+
+func (l *singleConnListener) Close() error {
+	select {
+	case <-l.closed:
+	default:
+		close(l.closed)
+	}
+	return nil
+}
+
+func (l *singleConnListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
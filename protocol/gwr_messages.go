@@ -0,0 +1,208 @@
+package protocol
+
+import (
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// gwrFile is the protoreflect.FileDescriptor for gwr.proto. There's no
+// protoc-gen-go in this build's toolchain, so it's built by hand here rather
+// than generated; the message types below wrap dynamicpb.Message against it,
+// so they satisfy proto.Message (the protoreflect-based v2 API) for real,
+// same as proto_format.go and protocol/proto_descriptor.go already require.
+var gwrFile protoreflect.FileDescriptor
+
+func init() {
+	str := func(s string) *string { return &s }
+	i32 := func(i int32) *int32 { return &i }
+
+	field := func(name string, num int32, typ descriptorpb.FieldDescriptorProto_Type, repeated bool) *descriptorpb.FieldDescriptorProto {
+		label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		if repeated {
+			label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		}
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     str(name),
+			Number:   i32(num),
+			Label:    label.Enum(),
+			Type:     typ.Enum(),
+			JsonName: str(name),
+		}
+	}
+	msg := func(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{Name: str(name), Field: fields}
+	}
+
+	const (
+		strT   = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		bytesT = descriptorpb.FieldDescriptorProto_TYPE_BYTES
+	)
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    str("gwr.proto"),
+		Package: str("gwr"),
+		Syntax:  str("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			msg("ListRequest"),
+			msg("ListResponse", field("names", 1, strT, true)),
+			msg("InfoRequest", field("name", 1, strT, false)),
+			msg("InfoResponse", field("formats", 1, strT, true), field("attrs", 2, bytesT, false)),
+			msg("GetRequest", field("name", 1, strT, false), field("format", 2, strT, false)),
+			msg("GetResponse", field("data", 1, bytesT, false)),
+			msg("WatchRequest", field("name", 1, strT, false), field("format", 2, strT, false)),
+			msg("WatchResponse", field("data", 1, bytesT, false)),
+		},
+	}
+
+	fd, err := protodesc.NewFile(fdp, nil)
+	if err != nil {
+		panic("protocol: building gwr.proto descriptor: " + err.Error())
+	}
+	gwrFile = fd
+}
+
+func messageDesc(name string) protoreflect.MessageDescriptor {
+	md := gwrFile.Messages().ByName(protoreflect.Name(name))
+	if md == nil {
+		panic("protocol: no such message " + name)
+	}
+	return md
+}
+
+func fieldOf(m *dynamicpb.Message, name string) protoreflect.FieldDescriptor {
+	return m.Descriptor().Fields().ByName(protoreflect.Name(name))
+}
+
+func getString(m *dynamicpb.Message, name string) string {
+	return m.Get(fieldOf(m, name)).String()
+}
+
+func setString(m *dynamicpb.Message, name, v string) {
+	m.Set(fieldOf(m, name), protoreflect.ValueOfString(v))
+}
+
+func getBytes(m *dynamicpb.Message, name string) []byte {
+	return m.Get(fieldOf(m, name)).Bytes()
+}
+
+func setBytes(m *dynamicpb.Message, name string, v []byte) {
+	m.Set(fieldOf(m, name), protoreflect.ValueOfBytes(v))
+}
+
+func getStrings(m *dynamicpb.Message, name string) []string {
+	list := m.Get(fieldOf(m, name)).List()
+	out := make([]string, list.Len())
+	for i := range out {
+		out[i] = list.Get(i).String()
+	}
+	return out
+}
+
+func setStrings(m *dynamicpb.Message, name string, vs []string) {
+	list := m.Mutable(fieldOf(m, name)).List()
+	for _, v := range vs {
+		list.Append(protoreflect.ValueOfString(v))
+	}
+}
+
+// ListRequest is the request for the List rpc.
+type ListRequest struct{ *dynamicpb.Message }
+
+func newListRequest() *ListRequest {
+	return &ListRequest{dynamicpb.NewMessage(messageDesc("ListRequest"))}
+}
+
+// ListResponse is the response for the List rpc.
+type ListResponse struct{ *dynamicpb.Message }
+
+func newListResponse(names []string) *ListResponse {
+	m := dynamicpb.NewMessage(messageDesc("ListResponse"))
+	setStrings(m, "names", names)
+	return &ListResponse{m}
+}
+
+// GetNames returns the response's names field.
+func (r *ListResponse) GetNames() []string { return getStrings(r.Message, "names") }
+
+// InfoRequest is the request for the Info rpc.
+type InfoRequest struct{ *dynamicpb.Message }
+
+func newInfoRequest(name string) *InfoRequest {
+	m := dynamicpb.NewMessage(messageDesc("InfoRequest"))
+	setString(m, "name", name)
+	return &InfoRequest{m}
+}
+
+// GetName returns the request's name field.
+func (r *InfoRequest) GetName() string { return getString(r.Message, "name") }
+
+// InfoResponse is the response for the Info rpc.
+type InfoResponse struct{ *dynamicpb.Message }
+
+func newInfoResponse(formats []string, attrs []byte) *InfoResponse {
+	m := dynamicpb.NewMessage(messageDesc("InfoResponse"))
+	setStrings(m, "formats", formats)
+	setBytes(m, "attrs", attrs)
+	return &InfoResponse{m}
+}
+
+// GetFormats returns the response's formats field.
+func (r *InfoResponse) GetFormats() []string { return getStrings(r.Message, "formats") }
+
+// GetAttrs returns the response's attrs field.
+func (r *InfoResponse) GetAttrs() []byte { return getBytes(r.Message, "attrs") }
+
+// GetRequest is the request for the Get rpc.
+type GetRequest struct{ *dynamicpb.Message }
+
+func newGetRequest(name, format string) *GetRequest {
+	m := dynamicpb.NewMessage(messageDesc("GetRequest"))
+	setString(m, "name", name)
+	setString(m, "format", format)
+	return &GetRequest{m}
+}
+
+// GetName returns the request's name field.
+func (r *GetRequest) GetName() string { return getString(r.Message, "name") }
+
+// GetFormat returns the request's format field.
+func (r *GetRequest) GetFormat() string { return getString(r.Message, "format") }
+
+// GetResponse is the response for the Get rpc.
+type GetResponse struct{ *dynamicpb.Message }
+
+func newGetResponse(data []byte) *GetResponse {
+	m := dynamicpb.NewMessage(messageDesc("GetResponse"))
+	setBytes(m, "data", data)
+	return &GetResponse{m}
+}
+
+// GetData returns the response's data field.
+func (r *GetResponse) GetData() []byte { return getBytes(r.Message, "data") }
+
+// WatchRequest is the request for the Watch rpc.
+type WatchRequest struct{ *dynamicpb.Message }
+
+func newWatchRequest() *WatchRequest {
+	return &WatchRequest{dynamicpb.NewMessage(messageDesc("WatchRequest"))}
+}
+
+// GetName returns the request's name field.
+func (r *WatchRequest) GetName() string { return getString(r.Message, "name") }
+
+// GetFormat returns the request's format field.
+func (r *WatchRequest) GetFormat() string { return getString(r.Message, "format") }
+
+// WatchResponse is one streamed item for the Watch rpc.
+type WatchResponse struct{ *dynamicpb.Message }
+
+func newWatchResponse(data []byte) *WatchResponse {
+	m := dynamicpb.NewMessage(messageDesc("WatchResponse"))
+	setBytes(m, "data", data)
+	return &WatchResponse{m}
+}
+
+// GetData returns the response's data field.
+func (r *WatchResponse) GetData() []byte { return getBytes(r.Message, "data") }
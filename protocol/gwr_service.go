@@ -0,0 +1,106 @@
+// Package protocol's GWR gRPC service plumbing: the GWRServer interface,
+// stream handle, and grpc.ServiceDesc the generated-by-hand message types in
+// gwr_messages.go are served under. There's no protoc-gen-go in this build's
+// toolchain, so this (and gwr_messages.go) is hand-written against the same
+// shape protoc-gen-go would emit, rather than actually generated.
+
+package protocol
+
+import (
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// GWRServer is the server API for the GWR service.
+type GWRServer interface {
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	Info(context.Context, *InfoRequest) (*InfoResponse, error)
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	Watch(*WatchRequest, GWR_WatchServer) error
+}
+
+// GWR_WatchServer is the server-side stream handle for the Watch rpc.
+type GWR_WatchServer interface {
+	Send(*WatchResponse) error
+	grpc.ServerStream
+}
+
+type gwrWatchServer struct {
+	grpc.ServerStream
+}
+
+func (s *gwrWatchServer) Send(m *WatchResponse) error {
+	return s.ServerStream.SendMsg(m)
+}
+
+// RegisterGWRServer registers srv with s under the GWR service descriptor.
+func RegisterGWRServer(s *grpc.Server, srv GWRServer) {
+	s.RegisterService(&gwrServiceDesc, srv)
+}
+
+func gwrListHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := newListRequest()
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GWRServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/gwr.GWR/List"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GWRServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func gwrInfoHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := newInfoRequest("")
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GWRServer).Info(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/gwr.GWR/Info"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GWRServer).Info(ctx, req.(*InfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func gwrGetHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := newGetRequest("", "")
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GWRServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/gwr.GWR/Get"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GWRServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func gwrWatchHandler(srv interface{}, stream grpc.ServerStream) error {
+	m := newWatchRequest()
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GWRServer).Watch(m, &gwrWatchServer{stream})
+}
+
+var gwrServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gwr.GWR",
+	HandlerType: (*GWRServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "List", Handler: gwrListHandler},
+		{MethodName: "Info", Handler: gwrInfoHandler},
+		{MethodName: "Get", Handler: gwrGetHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "Watch", Handler: gwrWatchHandler, ServerStreams: true},
+	},
+	Metadata: "gwr.proto",
+}
@@ -0,0 +1,36 @@
+package protocol
+
+import (
+	"net/http"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ProtoDescriptorSource is implemented by anything the gwr package's HTTP
+// handler looks up a data source's ".proto" sub-resource on, namely
+// *gwr.MarshaledDataSource.
+type ProtoDescriptorSource interface {
+	ProtoDescriptor() (protoreflect.MessageDescriptor, bool)
+}
+
+// WriteProtoDescriptor writes a data source's FileDescriptorProto, if it has
+// one, as application/x-protobuf; the gwr package's HTTP handler (which
+// wraps NewHTTPRest) serves this at "/gwr/<name>.proto", so clients can
+// discover the wire schema the "proto" format serializes. A source that
+// reports no descriptor gets a 404.
+func WriteProtoDescriptor(w http.ResponseWriter, src ProtoDescriptorSource) error {
+	md, ok := src.ProtoDescriptor()
+	if !ok {
+		http.Error(w, "no proto descriptor for this data source", http.StatusNotFound)
+		return nil
+	}
+	buf, err := proto.Marshal(protodesc.ToFileDescriptorProto(md.ParentFile()))
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, err = w.Write(buf)
+	return err
+}
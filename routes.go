@@ -0,0 +1,86 @@
+package gwr
+
+import (
+	"io"
+	"log"
+	"net/url"
+	"strings"
+)
+
+// Route describes one additional HTTP sub-resource a GenericDataSource wants
+// to expose under its "/gwr/<name>/..." namespace, beyond the standard Get
+// and Watch endpoints that protocol.NewHTTPRest already wires up for every
+// data source.
+type Route struct {
+	// Path is the sub-path beneath the data source's namespace, e.g. "tail"
+	// for "/gwr/log/tail".
+	Path string
+
+	// Method is the HTTP method this route answers, e.g. "GET".
+	Method string
+
+	// Handler is called with the request's query parameters already parsed,
+	// and returns the payload to marshal through the normal format
+	// negotiation pipeline (the same one Get uses). Path is matched exactly,
+	// so no path parameters are extracted from the request's sub-path.
+	Handler func(params url.Values) (interface{}, error)
+}
+
+// RoutedDataSource is implemented by a GenericDataSource that wants
+// parameterized, REST-ish reads (e.g. "/gwr/log/tail?n=100") instead of
+// reinventing HTTP handling itself.
+type RoutedDataSource interface {
+	GenericDataSource
+	HTTPRoutes() []Route
+}
+
+// MountedRoute is a Route bound to its owning MarshaledDataSource, ready to
+// be served by the gwr package's HTTP handler, which matches a request's
+// sub-path and method against it.
+type MountedRoute struct {
+	Path   string
+	Method string
+
+	mds   *MarshaledDataSource
+	route Route
+}
+
+// Call invokes the route's handler and marshals its result in formatName,
+// the same format negotiation MarshaledDataSource.Get uses, so content-type
+// selection via the Accept header continues to work uniformly.
+func (mr MountedRoute) Call(formatName string, params url.Values, w io.Writer) error {
+	format, ok := mr.mds.formats[strings.ToLower(formatName)]
+	if !ok {
+		return ErrUnsupportedFormat
+	}
+	data, err := mr.route.Handler(params)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return ErrNotGetable
+	}
+	buf, err := format.MarshalGet(data)
+	if err != nil {
+		log.Printf("route marshaling error %v", err)
+		return err
+	}
+	_, err = w.Write(buf)
+	return err
+}
+
+// Routes returns the additional HTTP routes the wrapped data source wants
+// mounted under its namespace, or nil if it doesn't implement
+// RoutedDataSource.
+func (mds *MarshaledDataSource) Routes() []MountedRoute {
+	rds, ok := mds.source.(RoutedDataSource)
+	if !ok {
+		return nil
+	}
+	routes := rds.HTTPRoutes()
+	mounted := make([]MountedRoute, len(routes))
+	for i, route := range routes {
+		mounted[i] = MountedRoute{Path: route.Path, Method: route.Method, mds: mds, route: route}
+	}
+	return mounted
+}
@@ -11,6 +11,10 @@ import (
 	"github.com/uber-go/gwr/source"
 
 	"github.com/uber-common/stacked"
+
+	"google.golang.org/grpc"
+
+	grpcprotocol "github.com/uber-go/gwr/protocol"
 )
 
 var errNoServer = errors.New("no server configured")
@@ -45,7 +49,7 @@ func (is indirectServer) Stop() error {
 
 func init() {
 	hh := protocol.NewHTTPRest(DefaultDataSources, "/gwr", indirectServer{&theServer})
-	http.Handle("/gwr/", hh)
+	http.Handle("/gwr/", withExtras(DefaultDataSources, "/gwr", hh))
 }
 
 // ListenAndServeResp starts a resp protocol gwr server.
@@ -62,11 +66,11 @@ func ListenAndServeHTTP(hostPort string, dss *source.DataSources) error {
 		dss = DefaultDataSources
 	}
 	hh := protocol.NewHTTPRest(dss, "", indirectServer{&theServer})
-	return http.ListenAndServe(hostPort, hh)
+	return http.ListenAndServe(hostPort, withExtras(dss, "", hh))
 }
 
-// NewServer creates an "auto" protocol server that will respond to HTTP or
-// RESP requests.
+// NewServer creates an "auto" protocol server that will respond to HTTP,
+// RESP, or gRPC requests.
 func NewServer(dss *source.DataSources) stacked.Server {
 	if dss == nil {
 		dss = DefaultDataSources
@@ -74,8 +78,9 @@ func NewServer(dss *source.DataSources) stacked.Server {
 	hh := protocol.NewHTTPRest(dss, "", indirectServer{&theServer})
 	rh := protocol.NewRedisHandler(dss)
 	return stacked.NewServer(
+		grpcDetector(dss),
 		respDetector(rh),
-		stacked.DefaultHTTPHandler(hh),
+		stacked.DefaultHTTPHandler(withExtras(dss, "", hh)),
 	)
 }
 
@@ -90,8 +95,54 @@ func respDetector(respHandler resp.RedisHandler) stacked.Detector {
 	}
 }
 
-// ListenAndServe starts an "auto" protocol server that will respond to HTTP or
-// RESP on the given hostPort.
+// grpcDetector recognizes the HTTP/2 connection preface so a single port can
+// also serve gRPC traffic alongside HTTP/1 and RESP; every matching
+// connection is handed to the same grpc.Server, one-shot-listener style, via
+// grpcprotocol.ServeGRPCConn.
+func grpcDetector(dss *source.DataSources) stacked.Detector {
+	gs := newGRPCServer(dss)
+	hndl := stacked.HandlerFunc(func(conn net.Conn, bufr *bufio.Reader) {
+		grpcprotocol.ServeGRPCConn(gs, &bufferedConn{Conn: conn, r: bufr})
+	})
+	return stacked.Detector{
+		Needed:  len(grpcprotocol.HTTP2Preface),
+		Test:    grpcprotocol.IsHTTP2Preface,
+		Handler: hndl,
+	}
+}
+
+func newGRPCServer(dss *source.DataSources) *grpc.Server {
+	gs := grpc.NewServer()
+	grpcprotocol.RegisterGWRServer(gs, grpcprotocol.NewGRPCServer(dss))
+	return gs
+}
+
+// bufferedConn lets a detected net.Conn be read through the bufio.Reader that
+// stacked.Server used to peek its protocol-detection bytes, so no bytes
+// observed during detection are lost.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (bc *bufferedConn) Read(p []byte) (int, error) {
+	return bc.r.Read(p)
+}
+
+// ListenAndServeGRPC starts a gRPC protocol gwr server.
+func ListenAndServeGRPC(hostPort string, dss *source.DataSources) error {
+	if dss == nil {
+		dss = DefaultDataSources
+	}
+	lis, err := net.Listen("tcp", hostPort)
+	if err != nil {
+		return err
+	}
+	return newGRPCServer(dss).Serve(lis)
+}
+
+// ListenAndServe starts an "auto" protocol server that will respond to HTTP,
+// RESP, or gRPC on the given hostPort.
 func ListenAndServe(hostPort string, dss *source.DataSources) error {
 	return NewServer(dss).ListenAndServe(hostPort)
 }
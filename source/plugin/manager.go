@@ -0,0 +1,148 @@
+package plugin
+
+import (
+	"log"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	plug "github.com/hashicorp/go-plugin"
+
+	"github.com/uber-go/gwr"
+	"github.com/uber-go/gwr/source"
+)
+
+// registry is the subset of *source.DataSources that Manager needs, so its
+// teardown logic can be exercised against a fake in tests without a live
+// source.DataSources.
+type registry interface {
+	Add(source.DataSource)
+	Remove(name string)
+}
+
+// Manager launches plugin executables found in a directory and registers
+// the GenericDataSources they contribute into a source.DataSources, tearing
+// each plugin's sources back down if its process dies.
+type Manager struct {
+	dss registry
+
+	mu      sync.Mutex
+	plugins map[string]*hostedPlugin
+}
+
+type hostedPlugin struct {
+	client  *plug.Client
+	cmd     *exec.Cmd
+	sources []*gwr.MarshaledDataSource
+}
+
+// NewManager creates a Manager that registers discovered plugins' data
+// sources into dss.
+func NewManager(dss *source.DataSources) *Manager {
+	return &Manager{dss: dss, plugins: make(map[string]*hostedPlugin)}
+}
+
+// LoadDir launches every executable in dir as a gwr plugin and registers the
+// GenericDataSources it contributes. A plugin that fails to start or to
+// dispense its data sources is logged and skipped, not fatal to the others.
+func (m *Manager) LoadDir(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := m.Load(path); err != nil {
+			log.Printf("gwr: plugin %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// Load launches the plugin executable at path and registers the
+// GenericDataSources it contributes.
+func (m *Manager) Load(path string) error {
+	cmd := exec.Command(path)
+	client := plug.NewClient(&plug.ClientConfig{
+		HandshakeConfig: Handshake,
+		Plugins:         Plugins,
+		Cmd:             cmd,
+	})
+
+	rpcConn, err := client.Client()
+	if err != nil {
+		client.Kill()
+		return err
+	}
+
+	raw, err := rpcConn.Dispense(pluginName)
+	if err != nil {
+		client.Kill()
+		return err
+	}
+
+	srcs, err := raw.(*rpcClient).Sources()
+	if err != nil {
+		client.Kill()
+		return err
+	}
+
+	hp := &hostedPlugin{client: client, cmd: cmd}
+	for _, src := range srcs {
+		mds := gwr.NewMarshaledDataSource(src, nil)
+		m.dss.Add(mds)
+		hp.sources = append(hp.sources, mds)
+	}
+
+	m.mu.Lock()
+	m.plugins[path] = hp
+	m.mu.Unlock()
+
+	go m.monitor(path, cmd)
+
+	return nil
+}
+
+// monitor waits for a plugin process to exit and tears down the data
+// sources it contributed.
+func (m *Manager) monitor(path string, cmd *exec.Cmd) {
+	cmd.Wait()
+	m.teardown(path)
+}
+
+// teardown removes path's hostedPlugin, if still present, unregistering its
+// data sources and closing any watchers still attached to them through
+// MarshaledDataSource.Close: a watcher's writer is closed if it implements
+// io.Closer; any other writer only stops receiving items.
+func (m *Manager) teardown(path string) {
+	m.mu.Lock()
+	hp, ok := m.plugins[path]
+	delete(m.plugins, path)
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	names := make([]string, len(hp.sources))
+	for i, mds := range hp.sources {
+		mds.Close()
+		m.dss.Remove(mds.Name())
+		names[i] = mds.Name()
+	}
+	log.Printf("gwr: plugin %s exited, removed sources %v", path, names)
+}
+
+// Close kills every plugin process this Manager started, removing their
+// data sources.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	paths := make([]string, 0, len(m.plugins))
+	for path, hp := range m.plugins {
+		hp.client.Kill()
+		paths = append(paths, path)
+	}
+	m.mu.Unlock()
+
+	for _, path := range paths {
+		m.teardown(path)
+	}
+}
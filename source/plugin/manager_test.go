@@ -0,0 +1,57 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+	"text/template"
+
+	"github.com/uber-go/gwr"
+	"github.com/uber-go/gwr/source"
+)
+
+// fakeSource is a minimal gwr.GenericDataSource for exercising Manager's
+// teardown path without a real plugin process.
+type fakeSource struct{ name string }
+
+func (s fakeSource) Name() string                    { return s.name }
+func (s fakeSource) Attrs() map[string]interface{}   { return nil }
+func (s fakeSource) TextTemplate() *template.Template { return nil }
+func (s fakeSource) Get() interface{}                { return nil }
+func (s fakeSource) GetInit() interface{}            { return nil }
+func (s fakeSource) Watch(gwr.GenericDataWatcher)    {}
+
+// fakeRegistry implements registry, recording every name Remove is called
+// with so tests can assert on teardown without a real source.DataSources.
+type fakeRegistry struct {
+	removed []string
+}
+
+func (r *fakeRegistry) Add(source.DataSource) {}
+
+func (r *fakeRegistry) Remove(name string) {
+	r.removed = append(r.removed, name)
+}
+
+func TestManagerTeardownRemovesAllSourcesForDeadPlugin(t *testing.T) {
+	fr := &fakeRegistry{}
+	m := &Manager{
+		dss: fr,
+		plugins: map[string]*hostedPlugin{
+			"plugin-path": {
+				sources: []*gwr.MarshaledDataSource{
+					gwr.NewMarshaledDataSource(fakeSource{name: "a"}, nil),
+					gwr.NewMarshaledDataSource(fakeSource{name: "b"}, nil),
+				},
+			},
+		},
+	}
+
+	m.teardown("plugin-path")
+
+	if want := []string{"a", "b"}; !reflect.DeepEqual(fr.removed, want) {
+		t.Fatalf("removed = %v, want %v", fr.removed, want)
+	}
+	if _, ok := m.plugins["plugin-path"]; ok {
+		t.Fatalf("teardown left plugin-path in m.plugins")
+	}
+}
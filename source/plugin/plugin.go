@@ -0,0 +1,91 @@
+// Package plugin lets external processes contribute GenericDataSource
+// implementations to a running gwr server over a hashicorp/go-plugin RPC
+// connection, so operators can add data sources without recompiling gwr.
+package plugin
+
+import (
+	"encoding/json"
+	"net/rpc"
+
+	plug "github.com/hashicorp/go-plugin"
+
+	"github.com/uber-go/gwr"
+)
+
+// Handshake is the go-plugin handshake both gwr and its plugins must agree
+// on before any RPC traffic is trusted.
+var Handshake = plug.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "GWR_PLUGIN",
+	MagicCookieValue: "data-source",
+}
+
+// pluginName is the go-plugin registration name a plugin process serves its
+// data sources under; see plug.ClientConfig.Plugins.
+const pluginName = "datasource"
+
+// Plugins is the plug.PluginSet both sides of the connection configure
+// their plug.ClientConfig/plug.ServeConfig with.
+var Plugins = plug.PluginSet{
+	pluginName: &DataSourcePlugin{},
+}
+
+// GenericDataSourceSet is implemented by a plugin process; it returns the
+// set of format-agnostic data sources the plugin wants to contribute.
+type GenericDataSourceSet interface {
+	Sources() []gwr.GenericDataSource
+}
+
+// DataSourcePlugin bridges a GenericDataSourceSet across the go-plugin RPC
+// boundary; a plugin process serves one with Impl set, and the host
+// dispenses one with Impl left nil to obtain an *rpcClient, whose Sources
+// returns host-side stand-ins for the plugin's data sources.
+type DataSourcePlugin struct {
+	Impl GenericDataSourceSet
+}
+
+// Server returns the net/rpc server a plugin process exposes its sources
+// through.
+func (p *DataSourcePlugin) Server(b *plug.MuxBroker) (interface{}, error) {
+	return newRPCServer(p.Impl, b), nil
+}
+
+// Client returns the host-side stand-in for a plugin's GenericDataSourceSet.
+func (p *DataSourcePlugin) Client(b *plug.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &rpcClient{client: c, broker: b}, nil
+}
+
+// namesReply is the reply of the Plugin.Names rpc.
+type namesReply struct {
+	Names []string
+}
+
+// nameArgs carries the name of the data source an rpc targets.
+type nameArgs struct {
+	Name string
+}
+
+// dataReply carries a json-marshaled GenericDataSource.Get/GetInit result;
+// data crosses the plugin boundary pre-marshaled to json so that arbitrary
+// plugin-defined types never need to be gob-registered on the host.
+type dataReply struct {
+	Data json.RawMessage
+}
+
+// attrsReply carries a data source's Attrs().
+type attrsReply struct {
+	Attrs map[string]interface{}
+}
+
+// watchArgs carries the broker connection id a plugin should push
+// GenericDataWatcher emissions over for the named source.
+type watchArgs struct {
+	Name     string
+	BrokerID uint32
+}
+
+// emitArgs is one item pushed back from a plugin over its Watch callback
+// connection.
+type emitArgs struct {
+	Data json.RawMessage
+}
@@ -0,0 +1,196 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/rpc"
+	"text/template"
+
+	plug "github.com/hashicorp/go-plugin"
+
+	"github.com/uber-go/gwr"
+)
+
+// rpcServer is the net/rpc receiver a plugin process registers; its methods
+// are called from the host across the RPC connection, and net/rpc may
+// dispatch them concurrently on the same connection, so bySourceName is
+// built once up front rather than lazily on first use.
+type rpcServer struct {
+	impl   GenericDataSourceSet
+	broker *plug.MuxBroker
+
+	bySourceName map[string]gwr.GenericDataSource
+}
+
+func newRPCServer(impl GenericDataSourceSet, b *plug.MuxBroker) *rpcServer {
+	bySourceName := make(map[string]gwr.GenericDataSource)
+	for _, src := range impl.Sources() {
+		bySourceName[src.Name()] = src
+	}
+	return &rpcServer{impl: impl, broker: b, bySourceName: bySourceName}
+}
+
+func (s *rpcServer) source(name string) gwr.GenericDataSource {
+	return s.bySourceName[name]
+}
+
+// Names returns the names of every source this plugin contributes.
+func (s *rpcServer) Names(args interface{}, reply *namesReply) error {
+	for _, src := range s.impl.Sources() {
+		reply.Names = append(reply.Names, src.Name())
+	}
+	return nil
+}
+
+// Attrs returns the named source's Attrs().
+func (s *rpcServer) Attrs(args *nameArgs, reply *attrsReply) error {
+	reply.Attrs = s.source(args.Name).Attrs()
+	return nil
+}
+
+// Get returns the named source's Get(), json-marshaled.
+func (s *rpcServer) Get(args *nameArgs, reply *dataReply) error {
+	data := s.source(args.Name).Get()
+	if data == nil {
+		return nil
+	}
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	reply.Data = buf
+	return nil
+}
+
+// GetInit returns the named source's GetInit(), json-marshaled.
+func (s *rpcServer) GetInit(args *nameArgs, reply *dataReply) error {
+	data := s.source(args.Name).GetInit()
+	if data == nil {
+		return nil
+	}
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	reply.Data = buf
+	return nil
+}
+
+// Watch starts forwarding the named source's watch emissions over a
+// callback RPC connection dialed back through the MuxBroker, and returns once
+// the watcher is set. Forwarding goes on until an Emit call fails (the host's
+// watcher returned false, or the connection is gone); the callback
+// connection is then closed and the watcher returns false.
+func (s *rpcServer) Watch(args *watchArgs, reply *struct{}) error {
+	conn, err := s.broker.Dial(args.BrokerID)
+	if err != nil {
+		return err
+	}
+	client := rpc.NewClient(conn)
+	src := s.source(args.Name)
+	src.Watch(func(item interface{}) bool {
+		buf, err := json.Marshal(item)
+		if err != nil {
+			return true // malformed item shouldn't kill the whole watch
+		}
+		if err := client.Call("Plugin.Emit", &emitArgs{Data: buf}, &struct{}{}); err != nil {
+			client.Close()
+			return false
+		}
+		return true
+	})
+	return nil
+}
+
+// rpcClient is the host-side net/rpc caller used to implement
+// gwr.GenericDataSource for each name a plugin advertises.
+type rpcClient struct {
+	client *rpc.Client
+	broker *plug.MuxBroker
+}
+
+// Sources returns one gwr.GenericDataSource per name the plugin advertises.
+func (c *rpcClient) Sources() ([]gwr.GenericDataSource, error) {
+	var reply namesReply
+	if err := c.client.Call("Plugin.Names", new(struct{}), &reply); err != nil {
+		return nil, err
+	}
+	srcs := make([]gwr.GenericDataSource, len(reply.Names))
+	for i, name := range reply.Names {
+		srcs[i] = &remoteSource{name: name, client: c.client, broker: c.broker}
+	}
+	return srcs, nil
+}
+
+// remoteSource implements gwr.GenericDataSource by forwarding every call
+// across the RPC connection to the plugin process that actually owns the
+// named data source.
+type remoteSource struct {
+	name   string
+	client *rpc.Client
+	broker *plug.MuxBroker
+}
+
+func (r *remoteSource) Name() string { return r.name }
+
+func (r *remoteSource) Attrs() map[string]interface{} {
+	var reply attrsReply
+	if err := r.client.Call("Plugin.Attrs", &nameArgs{Name: r.name}, &reply); err != nil {
+		return nil
+	}
+	return reply.Attrs
+}
+
+// TextTemplate is always nil for a plugin source; json is the only format
+// guaranteed to survive the RPC boundary.
+func (r *remoteSource) TextTemplate() *template.Template { return nil }
+
+func (r *remoteSource) Get() interface{} {
+	var reply dataReply
+	if err := r.client.Call("Plugin.Get", &nameArgs{Name: r.name}, &reply); err != nil || reply.Data == nil {
+		return nil
+	}
+	return reply.Data
+}
+
+func (r *remoteSource) GetInit() interface{} {
+	var reply dataReply
+	if err := r.client.Call("Plugin.GetInit", &nameArgs{Name: r.name}, &reply); err != nil || reply.Data == nil {
+		return nil
+	}
+	return reply.Data
+}
+
+// Watch opens a callback connection over the MuxBroker and returns
+// immediately, same as every other GenericDataSource.Watch implementation;
+// the plugin's Watch call returns as soon as it has set its watcher, and the
+// broker connection keeps serving Emit calls in the background until the
+// plugin closes it, which it does once an Emit fails (normally because
+// watcher returned false) or the connection dies out from under it.
+func (r *remoteSource) Watch(watcher gwr.GenericDataWatcher) {
+	id := r.broker.NextId()
+	go r.broker.AcceptAndServe(id, &callbackServer{watcher: watcher})
+
+	go func() {
+		call := r.client.Go("Plugin.Watch", &watchArgs{Name: r.name, BrokerID: id}, new(struct{}), nil)
+		<-call.Done
+	}()
+}
+
+// callbackServer is the net/rpc receiver the host exposes over a broker
+// connection so a plugin process can push watch emissions back.
+type callbackServer struct {
+	watcher gwr.GenericDataWatcher
+}
+
+var errWatchDone = errors.New("watcher done")
+
+// Emit delivers one item to the local GenericDataWatcher, and fails with
+// errWatchDone once that watcher returns false, so the plugin stops
+// forwarding.
+func (c *callbackServer) Emit(args *emitArgs, reply *struct{}) error {
+	if !c.watcher(args.Data) {
+		return errWatchDone
+	}
+	return nil
+}